@@ -0,0 +1,225 @@
+package govaluate
+
+import (
+	"fmt"
+
+	"github.com/wilebeast/govaluate/ast"
+)
+
+/*
+AST builds a public, walkable mirror of this expression's token stream, rooted
+at a single node. Use ast.Walk or ast.Transform on the result for
+constant-folding, query translation, or static analysis that the Tokens()-only
+surface can't support.
+
+The mirror is built directly from Tokens() rather than from the unexported
+evaluationStage tree, but it re-derives operator precedence and associativity
+from astOperatorPrecedence via a precedence-climbing parse, the same general
+technique planStages itself must use - so `1 + 2 * 3` builds as
+Binary{+, 1, Binary{*, 2, 3}}, not a left-leaning chain in token order.
+Short-circuiting operators (&&, ||, ??) build an ast.ShortCircuit instead of
+ast.Binary; `a ? b : c` builds an ast.Ternary.
+*/
+func (this EvaluableExpression) AST() (X1 ast.Node) {
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("AST", map[string]interface{}{})
+		defer func() {
+			tracer.OnExit("AST", map[string]interface{}{"X1": X1}, nil)
+		}()
+	}
+
+	node, _ := parseClause(this.tokens, 0)
+	return node
+}
+
+/*
+astOperatorPrecedence assigns each binary operator's textual form a binding
+strength; parseExpression splits at the lowest-precedence operator last
+(folding it outermost), consistent with the usual "+ binds looser than *"
+convention. Operators absent from this table (e.g. an unrecognized custom
+operator symbol) are treated as unknown and left unconsumed by
+parseExpression, ending the expression at that point - the same as reaching a
+CLAUSE_CLOSE or SEPARATOR.
+*/
+var astOperatorPrecedence = map[string]int{
+	"??": 0,
+	"||": 1,
+	"&&": 2,
+	"==": 3,
+	"!=": 3,
+	"<":  3,
+	"<=": 3,
+	">":  3,
+	">=": 3,
+	"=~": 3,
+	"!~": 3,
+	"+":  4,
+	"-":  4,
+	"*":  5,
+	"/":  5,
+	"%":  5,
+	"**": 6,
+}
+
+func isShortCircuitOperator(operator string) bool {
+	return operator == "&&" || operator == "||" || operator == "??"
+}
+
+/*
+clauseItem is one element of the flat operand/operator sequence
+collectClauseItems produces for a single clause - either a fully-built operand
+(a literal, variable, call, or parenthesized sub-expression already reduced to
+one Node) or a bare operator's textual form.
+*/
+type clauseItem struct {
+	operand  ast.Node
+	operator string
+}
+
+/*
+parseClause collects the flat item sequence for the clause starting at
+[position] and reduces it to a single Node via parseExpression, returning that
+Node along with the position of the CLAUSE_CLOSE, SEPARATOR, or end-of-stream
+token collectClauseItems stopped at.
+*/
+func parseClause(tokens []ExpressionToken, position int) (ast.Node, int) {
+	items, next := collectClauseItems(tokens, position)
+	node, _ := parseExpression(items, 0, 0)
+	return node, next
+}
+
+/*
+collectClauseItems walks tokens starting at [position], producing one
+clauseItem per operand or operator, until it reaches a CLAUSE_CLOSE, a
+SEPARATOR, or the end of the stream. A nested CLAUSE is parsed recursively via
+parseClause into a single operand item; a FUNCTION's argument list is parsed
+the same way, one argument per parseClause call, split on SEPARATOR.
+*/
+func collectClauseItems(tokens []ExpressionToken, position int) ([]clauseItem, int) {
+	var items []clauseItem
+
+	for position < len(tokens) {
+		token := tokens[position]
+
+		switch token.Kind {
+		case CLAUSE_CLOSE, SEPARATOR:
+			return items, position
+
+		case CLAUSE:
+			child, next := parseClause(tokens, position+1)
+			items = append(items, clauseItem{operand: child})
+			position = next + 1
+
+		case FUNCTION:
+			call := &ast.Call{Name: fmt.Sprintf("%v", token.Value)}
+			position++
+
+			if position < len(tokens) && tokens[position].Kind == CLAUSE {
+				position++
+				for position < len(tokens) && tokens[position].Kind != CLAUSE_CLOSE {
+					var arg ast.Node
+					arg, position = parseClause(tokens, position)
+					if arg != nil {
+						call.Arguments = append(call.Arguments, arg)
+					}
+					if position < len(tokens) && tokens[position].Kind == SEPARATOR {
+						position++
+					}
+				}
+				position++
+			}
+
+			items = append(items, clauseItem{operand: call})
+
+		case VARIABLE:
+			items = append(items, clauseItem{operand: &ast.Variable{Name: fmt.Sprintf("%v", token.Value)}})
+			position++
+
+		case NUMERIC, STRING, BOOLEAN:
+			items = append(items, clauseItem{operand: &ast.Literal{Value: token.Value}})
+			position++
+
+		default:
+			items = append(items, clauseItem{operator: fmt.Sprintf("%v", token.Value)})
+			position++
+		}
+	}
+
+	return items, position
+}
+
+/*
+parsePrimary consumes a single operand starting at items[idx]: either an
+already-built operand item, or - when items[idx] is an operator, meaning it
+appears where an operand was expected - a prefix unary application of that
+operator to the operand that follows it.
+*/
+func parsePrimary(items []clauseItem, idx int) (ast.Node, int) {
+	if idx >= len(items) {
+		return nil, idx
+	}
+
+	item := items[idx]
+	if item.operand != nil {
+		return item.operand, idx + 1
+	}
+
+	operand, next := parsePrimary(items, idx+1)
+	return &ast.Unary{Operator: item.operator, Operand: operand}, next
+}
+
+/*
+parseExpression is a standard precedence-climbing parser over [items]: it
+parses one primary operand, then repeatedly folds in following operators
+whose precedence is at least [minPrecedence], recursing with a strictly higher
+minimum to parse each operator's right-hand side. This gives the same
+associativity and precedence behavior planStages' own evaluation-stage
+construction does, without needing access to it.
+*/
+func parseExpression(items []clauseItem, idx int, minPrecedence int) (ast.Node, int) {
+	left, idx := parsePrimary(items, idx)
+
+	for idx < len(items) && items[idx].operand == nil {
+		operator := items[idx].operator
+
+		if operator == "?" {
+			// Ternary binds looser than every entry in astOperatorPrecedence, so
+			// it's only consumed at the outermost level of an expression
+			// (minPrecedence <= 0) - a tighter binary operator's right-hand side
+			// (parsed with minPrecedence == precedence+1 > 0) must leave it for
+			// the enclosing call to pick up instead.
+			if minPrecedence > 0 {
+				break
+			}
+
+			trueBranch, next := parseExpression(items, idx+1, 0)
+			idx = next
+
+			var falseBranch ast.Node
+			if idx < len(items) && items[idx].operator == ":" {
+				falseBranch, idx = parseExpression(items, idx+1, 0)
+			}
+
+			left = &ast.Ternary{Condition: left, TrueBranch: trueBranch, FalseBranch: falseBranch}
+			continue
+		}
+
+		precedence, known := astOperatorPrecedence[operator]
+		if !known || precedence < minPrecedence {
+			break
+		}
+
+		idx++
+		right, next := parseExpression(items, idx, precedence+1)
+		idx = next
+
+		if isShortCircuitOperator(operator) {
+			left = &ast.ShortCircuit{Operator: operator, Left: left, Right: right}
+		} else {
+			left = &ast.Binary{Operator: operator, Left: left, Right: right}
+		}
+	}
+
+	return left, idx
+}
@@ -0,0 +1,123 @@
+package govaluate
+
+import (
+	"testing"
+
+	"github.com/wilebeast/govaluate/ast"
+)
+
+func TestASTRespectsOperatorPrecedence(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + 2 * 3")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	node := expr.AST()
+	binary, ok := node.(*ast.Binary)
+	if !ok {
+		t.Fatalf("expected root node to be *ast.Binary, got %T", node)
+	}
+	if binary.Operator != "+" {
+		t.Fatalf("expected root operator '+', got '%s'", binary.Operator)
+	}
+
+	right, ok := binary.Right.(*ast.Binary)
+	if !ok {
+		t.Fatalf("expected right operand to be *ast.Binary, got %T", binary.Right)
+	}
+	if right.Operator != "*" {
+		t.Fatalf("expected right operator '*', got '%s'", right.Operator)
+	}
+}
+
+func TestASTBuildsShortCircuitNode(t *testing.T) {
+	expr, err := NewEvaluableExpression("true && false")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	node := expr.AST()
+	if _, ok := node.(*ast.ShortCircuit); !ok {
+		t.Fatalf("expected root node to be *ast.ShortCircuit, got %T", node)
+	}
+}
+
+func TestASTBuildsTernaryNode(t *testing.T) {
+	expr, err := NewEvaluableExpression("true ? 1 : 2")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	node := expr.AST()
+	ternary, ok := node.(*ast.Ternary)
+	if !ok {
+		t.Fatalf("expected root node to be *ast.Ternary, got %T", node)
+	}
+
+	if _, ok := ternary.TrueBranch.(*ast.Literal); !ok {
+		t.Fatalf("expected true branch to be *ast.Literal, got %T", ternary.TrueBranch)
+	}
+	if _, ok := ternary.FalseBranch.(*ast.Literal); !ok {
+		t.Fatalf("expected false branch to be *ast.Literal, got %T", ternary.FalseBranch)
+	}
+}
+
+func TestParseExpressionConsumesEntireTernary(t *testing.T) {
+	items := []clauseItem{
+		{operand: &ast.Literal{Value: true}},
+		{operator: "?"},
+		{operand: &ast.Literal{Value: 1.0}},
+		{operator: ":"},
+		{operand: &ast.Literal{Value: 2.0}},
+	}
+
+	node, consumed := parseExpression(items, 0, 0)
+
+	if consumed != len(items) {
+		t.Fatalf("expected parseExpression to consume all %d items, consumed %d", len(items), consumed)
+	}
+	if _, ok := node.(*ast.Ternary); !ok {
+		t.Fatalf("expected *ast.Ternary, got %T", node)
+	}
+}
+
+func TestASTBuildsTernaryInsideParentheses(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + (true ? 2 : 3)")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	node := expr.AST()
+	binary, ok := node.(*ast.Binary)
+	if !ok {
+		t.Fatalf("expected root node to be *ast.Binary, got %T", node)
+	}
+
+	if _, ok := binary.Right.(*ast.Ternary); !ok {
+		t.Fatalf("expected right operand to be *ast.Ternary, got %T", binary.Right)
+	}
+}
+
+func TestASTRespectsParentheses(t *testing.T) {
+	expr, err := NewEvaluableExpression("(1 + 2) * 3")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	node := expr.AST()
+	binary, ok := node.(*ast.Binary)
+	if !ok {
+		t.Fatalf("expected root node to be *ast.Binary, got %T", node)
+	}
+	if binary.Operator != "*" {
+		t.Fatalf("expected root operator '*', got '%s'", binary.Operator)
+	}
+
+	left, ok := binary.Left.(*ast.Binary)
+	if !ok {
+		t.Fatalf("expected left operand to be *ast.Binary, got %T", binary.Left)
+	}
+	if left.Operator != "+" {
+		t.Fatalf("expected left operator '+', got '%s'", left.Operator)
+	}
+}
@@ -0,0 +1,419 @@
+package govaluate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+accessorStep is a single link in a dot/bracket accessor chain, e.g. the `.Bar`,
+`.Bar()`, or `["key"]` segment of `foo.Bar["key"]`.
+*/
+type accessorStep struct {
+	// field or method name for a dot-access step, empty for a bracket-access step.
+	name string
+
+	// index/key for a bracket-access step, nil for a dot-access step. Only
+	// string and int literals are supported - see scanAccessorSteps.
+	key interface{}
+
+	// true if this step is a method call (`.Bar()`) rather than a field/index lookup.
+	isCall bool
+
+	// the method's sole argument for a one-arg call (`.Bar(1)`), unset for a
+	// zero-arg call or a non-call step. Only string, int, float64, and bool
+	// literals are supported - see scanAccessorSteps.
+	arg interface{}
+
+	// true if arg was actually supplied, distinguishing a one-arg call from a
+	// zero-arg call whose arg happens to be nil.
+	hasArg bool
+}
+
+/*
+NewEvaluableExpressionWithAccessors is the entry point for expressions that use
+Go-like struct/map/slice accessors - foo.Bar, foo.Bar(), foo.Bar.Baz,
+foo["key"], arr[0] - on top of whatever [functions] the caller also wants
+available. It works around the fact that the tokenizer and stage planner in
+this tree (parsing.go, not present here) don't know about accessor syntax: it
+rewrites every accessor chain it finds in [expression] into a call to a
+synthesized, uniquely-named ExpressionFunction that performs the chain walk via
+reflection, then parses the rewritten text with
+NewEvaluableExpressionWithFunctions. Vars() on the result still reports each
+chain's root variable correctly, since after rewriting the root is simply that
+function call's sole argument.
+*/
+func NewEvaluableExpressionWithAccessors(expression string, functions map[string]ExpressionFunction) (*EvaluableExpression, error) {
+	rewritten, accessorFuncs, err := rewriteAccessors(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]ExpressionFunction, len(functions)+len(accessorFuncs))
+	for name, fn := range functions {
+		merged[name] = fn
+	}
+	for name, fn := range accessorFuncs {
+		merged[name] = fn
+	}
+
+	return NewEvaluableExpressionWithFunctions(rewritten, merged)
+}
+
+/*
+rewriteAccessors scans [expression] for identifier runs immediately followed by
+a `.` or `[` accessor chain and replaces each one with a call to a freshly
+synthesized function name, returning the rewritten text alongside the
+ExpressionFunctions those names resolve to.
+*/
+func rewriteAccessors(expression string) (string, map[string]ExpressionFunction, error) {
+	var out strings.Builder
+	funcs := make(map[string]ExpressionFunction)
+	counter := 0
+	var inQuote byte
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+
+		if inQuote != 0 {
+			out.WriteByte(c)
+			if c == inQuote && expression[i-1] != '\\' {
+				inQuote = 0
+			}
+			i++
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			inQuote = c
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		if isIdentStart(c) && (i == 0 || expression[i-1] != '.') {
+			start := i
+			j := i + 1
+			for j < len(expression) && isIdentPart(expression[j]) {
+				j++
+			}
+			root := expression[start:j]
+
+			steps, end, err := scanAccessorSteps(expression, j)
+			if err != nil {
+				return "", nil, err
+			}
+
+			if len(steps) == 0 {
+				out.WriteString(root)
+				i = j
+				continue
+			}
+
+			name := fmt.Sprintf("__govaluate_accessor_%d__", counter)
+			counter++
+			funcs[name] = makeAccessorFunction(root, steps)
+
+			out.WriteString(name)
+			out.WriteByte('(')
+			out.WriteString(root)
+			out.WriteByte(')')
+			i = end
+			continue
+		}
+
+		out.WriteByte(c)
+		i++
+	}
+
+	return out.String(), funcs, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+/*
+scanAccessorSteps parses as many `.name`, `.name()`, and `[literal]` steps as
+it can starting at [position], returning the position just past the last step
+consumed. Bracket steps only accept a quoted string or an integer literal
+inside - not an arbitrary sub-expression - since evaluating one would require
+re-entering the expression parser this accessor rewrite is standing in for.
+*/
+func scanAccessorSteps(expression string, position int) ([]accessorStep, int, error) {
+	var steps []accessorStep
+
+	for position < len(expression) {
+		switch expression[position] {
+		case '.':
+			position++
+			start := position
+			for position < len(expression) && isIdentPart(expression[position]) {
+				position++
+			}
+			if position == start {
+				return nil, position, errors.New("expected a field or method name after '.'")
+			}
+			name := expression[start:position]
+
+			isCall := false
+			var arg interface{}
+			hasArg := false
+			if position < len(expression) && expression[position] == '(' {
+				isCall = true
+				position++
+
+				argStart := position
+				for position < len(expression) && expression[position] != ')' {
+					position++
+				}
+				if position >= len(expression) {
+					return nil, position, errors.New("unterminated '(' in accessor chain")
+				}
+
+				argText := strings.TrimSpace(expression[argStart:position])
+				if argText != "" {
+					if strings.ContainsRune(argText, ',') {
+						return nil, position, errors.New("accessor method calls accept at most one argument")
+					}
+
+					parsed, err := parseAccessorLiteral(argText)
+					if err != nil {
+						return nil, position, err
+					}
+					arg, hasArg = parsed, true
+				}
+
+				position++ // consume ')'
+			}
+
+			steps = append(steps, accessorStep{name: name, isCall: isCall, arg: arg, hasArg: hasArg})
+
+		case '[':
+			position++
+			start := position
+			depth := 1
+			for position < len(expression) && depth > 0 {
+				switch expression[position] {
+				case '[':
+					depth++
+				case ']':
+					depth--
+					if depth == 0 {
+						continue
+					}
+				}
+				position++
+			}
+			if depth != 0 {
+				return nil, position, errors.New("unterminated '[' in accessor chain")
+			}
+
+			key, err := parseAccessorKeyLiteral(expression[start:position])
+			if err != nil {
+				return nil, position, err
+			}
+			position++ // consume ']'
+
+			steps = append(steps, accessorStep{key: key})
+
+		default:
+			return steps, position, nil
+		}
+	}
+
+	return steps, position, nil
+}
+
+func parseAccessorKeyLiteral(literal string) (interface{}, error) {
+	literal = strings.TrimSpace(literal)
+
+	if len(literal) >= 2 {
+		quote := literal[0]
+		if (quote == '"' || quote == '\'') && literal[len(literal)-1] == quote {
+			return literal[1 : len(literal)-1], nil
+		}
+	}
+
+	if index, err := strconv.Atoi(literal); err == nil {
+		return index, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("unsupported index expression '%s' - accessor brackets accept only literal strings and integers", literal))
+}
+
+/*
+parseAccessorLiteral parses a single method-call argument literal - a quoted
+string, an int, a float64, or a bool - the same restriction scanAccessorSteps
+places on bracket keys, for the same reason: accepting an arbitrary
+sub-expression here would mean re-entering the expression parser this
+accessor rewrite stands in for.
+*/
+func parseAccessorLiteral(literal string) (interface{}, error) {
+	if key, err := parseAccessorKeyLiteral(literal); err == nil {
+		return key, nil
+	}
+
+	if literal == "true" {
+		return true, nil
+	}
+	if literal == "false" {
+		return false, nil
+	}
+
+	if value, err := strconv.ParseFloat(literal, 64); err == nil {
+		return value, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("unsupported argument expression '%s' - accessor method calls accept only literal strings, numbers, and booleans", literal))
+}
+
+/*
+makeAccessorFunction returns an ExpressionFunction that, given the single
+argument value an accessor chain's root variable evaluated to, walks [steps]
+against it in order via reflection.
+*/
+func makeAccessorFunction(root string, steps []accessorStep) ExpressionFunction {
+	return func(arguments ...interface{}) (interface{}, error) {
+		if len(arguments) != 1 {
+			return nil, errors.New(fmt.Sprintf("accessor for '%s' expected exactly one argument, got %d", root, len(arguments)))
+		}
+
+		current := arguments[0]
+		var err error
+
+		for _, step := range steps {
+			current, err = resolveAccessorStep(current, step)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("unable to resolve '%s' on variable '%s': %s", describeStep(step), root, err.Error()))
+			}
+		}
+
+		return current, nil
+	}
+}
+
+func describeStep(step accessorStep) string {
+	if step.name != "" {
+		return step.name
+	}
+	return fmt.Sprintf("[%v]", step.key)
+}
+
+/*
+resolveAccessorStep applies a single accessorStep to [current]. A method call
+is resolved against [current] as-is, before any pointer/interface
+dereferencing, since a pointer-receiver method only appears in a pointer's
+method set - dereferencing first (as a field/index lookup needs to) would
+strip it away and make the method unreachable. Field and index lookups still
+dereference pointers and interfaces as needed before inspecting the underlying
+kind.
+*/
+func resolveAccessorStep(current interface{}, step accessorStep) (interface{}, error) {
+	value := reflect.ValueOf(current)
+
+	if step.isCall {
+		return callAccessorMethod(value, step)
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, errors.New("nil pointer dereference")
+		}
+		value = value.Elem()
+	}
+
+	if step.name != "" {
+		return resolveNamedStep(value, step)
+	}
+
+	return resolveKeyedStep(value, step.key)
+}
+
+/*
+callAccessorMethod resolves and calls step.name on [value], trying [value]
+itself first (so a pointer-receiver method is found while value is still a
+pointer) and falling back to its dereferenced form (so a value-receiver
+method is still found when [value] is a pointer to it).
+*/
+func callAccessorMethod(value reflect.Value, step accessorStep) (interface{}, error) {
+	method := value.MethodByName(step.name)
+	if !method.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && !value.IsNil() {
+		method = value.Elem().MethodByName(step.name)
+	}
+	if !method.IsValid() {
+		return nil, errors.New(fmt.Sprintf("no method '%s'", step.name))
+	}
+
+	methodType := method.Type()
+	var args []reflect.Value
+	if step.hasArg {
+		if methodType.NumIn() != 1 {
+			return nil, errors.New(fmt.Sprintf("method '%s' takes %d arguments, accessor call supplied 1", step.name, methodType.NumIn()))
+		}
+		argValue := reflect.ValueOf(step.arg)
+		if !argValue.Type().AssignableTo(methodType.In(0)) {
+			return nil, errors.New(fmt.Sprintf("method '%s' argument type %s is not assignable from %s", step.name, methodType.In(0), argValue.Type()))
+		}
+		args = []reflect.Value{argValue}
+	} else if methodType.NumIn() != 0 {
+		return nil, errors.New(fmt.Sprintf("method '%s' takes %d arguments, accessor call supplied 0", step.name, methodType.NumIn()))
+	}
+
+	results := method.Call(args)
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return results[0].Interface(), nil
+}
+
+func resolveNamedStep(value reflect.Value, step accessorStep) (interface{}, error) {
+	switch value.Kind() {
+	case reflect.Struct:
+		field := value.FieldByName(step.name)
+		if !field.IsValid() {
+			return nil, errors.New(fmt.Sprintf("no field '%s'", step.name))
+		}
+		if !field.CanInterface() {
+			return nil, errors.New(fmt.Sprintf("field '%s' is not exported", step.name))
+		}
+		return field.Interface(), nil
+	case reflect.Map:
+		entry := value.MapIndex(reflect.ValueOf(step.name))
+		if !entry.IsValid() {
+			return nil, nil
+		}
+		return entry.Interface(), nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("cannot access '%s' on kind %s", step.name, value.Kind().String()))
+}
+
+func resolveKeyedStep(value reflect.Value, key interface{}) (interface{}, error) {
+	switch value.Kind() {
+	case reflect.Map:
+		entry := value.MapIndex(reflect.ValueOf(key))
+		if !entry.IsValid() {
+			return nil, nil
+		}
+		return entry.Interface(), nil
+	case reflect.Slice, reflect.Array:
+		index, ok := key.(int)
+		if !ok {
+			return nil, errors.New("index must be an integer")
+		}
+		if index < 0 || index >= value.Len() {
+			return nil, errors.New(fmt.Sprintf("index %d out of range", index))
+		}
+		return value.Index(index).Interface(), nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("cannot index kind %s", value.Kind().String()))
+}
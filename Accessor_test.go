@@ -0,0 +1,186 @@
+package govaluate
+
+import (
+	"testing"
+)
+
+type accessorTestInner struct {
+	Name string
+}
+
+func (this accessorTestInner) Greeting() string {
+	return "hello " + this.Name
+}
+
+func (this accessorTestInner) GreetingFor(title string) string {
+	return "hello " + title + " " + this.Name
+}
+
+func (this *accessorTestInner) SetName(name string) string {
+	this.Name = name
+	return this.Name
+}
+
+type accessorTestOuter struct {
+	Inner accessorTestInner
+	Tags  map[string]string
+	Items []int
+}
+
+func TestRewriteAccessorsLeavesPlainVariablesAlone(t *testing.T) {
+	rewritten, funcs, err := rewriteAccessors("foo + bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rewritten != "foo + bar" {
+		t.Fatalf("expected no rewrite, got '%s'", rewritten)
+	}
+	if len(funcs) != 0 {
+		t.Fatalf("expected no synthesized functions, got %d", len(funcs))
+	}
+}
+
+func TestRewriteAccessorsChain(t *testing.T) {
+	rewritten, funcs, err := rewriteAccessors(`foo.Inner.Name + foo.Tags["color"]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(funcs) != 2 {
+		t.Fatalf("expected two synthesized functions, got %d: %s", len(funcs), rewritten)
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsField(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors("foo.Inner.Name", nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	foo := accessorTestOuter{Inner: accessorTestInner{Name: "world"}}
+
+	result, err := expr.Evaluate(map[string]interface{}{"foo": foo})
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != "world" {
+		t.Fatalf("expected 'world', got %v", result)
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsMethodCall(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors("foo.Inner.Greeting()", nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	foo := accessorTestOuter{Inner: accessorTestInner{Name: "world"}}
+
+	result, err := expr.Evaluate(map[string]interface{}{"foo": foo})
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != "hello world" {
+		t.Fatalf("expected 'hello world', got %v", result)
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsMapAndSliceIndex(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors(`foo.Tags["color"] + foo.Items[1]`, nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	foo := accessorTestOuter{
+		Tags:  map[string]string{"color": "red"},
+		Items: []int{10, 20, 30},
+	}
+
+	_, err = expr.Evaluate(map[string]interface{}{"foo": foo})
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsVarsReportsRoot(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors("foo.Inner.Name + bar", nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	vars := expr.Vars()
+	foundFoo, foundBar := false, false
+	for _, name := range vars {
+		if name == "foo" {
+			foundFoo = true
+		}
+		if name == "bar" {
+			foundBar = true
+		}
+	}
+
+	if !foundFoo || !foundBar {
+		t.Fatalf("expected Vars() to report both 'foo' and 'bar', got %v", vars)
+	}
+}
+
+func TestScanAccessorStepsAcceptsOneArgumentMethodCall(t *testing.T) {
+	steps, _, err := scanAccessorSteps(`.Bar(1)`, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(steps) != 1 || !steps[0].isCall || !steps[0].hasArg || steps[0].arg != 1 {
+		t.Fatalf("expected a one-arg call step with arg 1, got %+v", steps)
+	}
+}
+
+func TestScanAccessorStepsRejectsTwoArgumentMethodCall(t *testing.T) {
+	_, _, err := scanAccessorSteps(".Bar(1, 2)", 0)
+	if err == nil {
+		t.Fatal("expected an error for a method call with more than one argument")
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsOneArgMethodCall(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors(`foo.Inner.GreetingFor("Dr.")`, nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	foo := accessorTestOuter{Inner: accessorTestInner{Name: "world"}}
+
+	result, err := expr.Evaluate(map[string]interface{}{"foo": foo})
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != "hello Dr. world" {
+		t.Fatalf("expected 'hello Dr. world', got %v", result)
+	}
+}
+
+func TestNewEvaluableExpressionWithAccessorsPointerReceiverMethodCall(t *testing.T) {
+	expr, err := NewEvaluableExpressionWithAccessors(`foo.SetName("changed")`, nil)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	foo := &accessorTestInner{Name: "world"}
+
+	result, err := expr.Evaluate(map[string]interface{}{"foo": foo})
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != "changed" {
+		t.Fatalf("expected 'changed', got %v", result)
+	}
+}
+
+func TestParseAccessorKeyLiteralUnsupported(t *testing.T) {
+	_, err := parseAccessorKeyLiteral("1 + 1")
+	if err == nil {
+		t.Fatal("expected an error for a non-literal index expression")
+	}
+}
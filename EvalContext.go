@@ -0,0 +1,232 @@
+package govaluate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+/*
+ExpressionFunctionWithContext is the context-aware counterpart to
+ExpressionFunction. Functions written with this signature take a
+context.Context as their first argument, so they can bound slow work - an HTTP
+lookup, a DB fetch - to that context's deadline or cancellation. The context
+they receive is the one bound to them by AdaptContextFunction; the ctx given
+to EvalContext is not passed to them.
+
+There is no separate function-registration path for this signature - the
+functions map accepted by NewEvaluableExpressionWithFunctions and Language
+always holds plain ExpressionFunctions. Use AdaptContextFunction to bind a
+specific context to an ExpressionFunctionWithContext, producing the
+ExpressionFunction to register instead.
+*/
+type ExpressionFunctionWithContext func(ctx context.Context, arguments ...interface{}) (interface{}, error)
+
+/*
+AdaptContextFunction binds [ctx] to [fn], returning a plain ExpressionFunction
+suitable for registration in a functions map. The binding is fixed by this
+call and involves no field on EvaluableExpression: the returned function always
+passes [ctx] to [fn], whatever context a later EvalContext call is given.
+
+Since functions are registered when the expression is parsed, adapting a new
+context for a later call means re-parsing the expression (e.g. with
+NewEvaluableExpressionWithFunctions) against a functions map built with that
+context's adapted functions.
+*/
+func AdaptContextFunction(ctx context.Context, fn ExpressionFunctionWithContext) ExpressionFunction {
+	return func(arguments ...interface{}) (interface{}, error) {
+		return fn(ctx, arguments...)
+	}
+}
+
+/*
+EvalContext runs the entire expression using the given [parameters], the same as
+Eval, but checks [ctx] for cancellation between stages and before invoking each
+operator or function. If [ctx] is cancelled or its deadline elapses partway
+through evaluation, EvalContext returns early with ctx.Err() rather than running
+to completion.
+
+This is the entry point to use when an expression may call a user-defined
+function that does slow I/O, since plain Eval has no way to bound how long that
+function, or the stage recursion around it, is allowed to run.
+*/
+func (this EvaluableExpression) EvalContext(ctx context.Context, parameters Parameters) (X1 interface{}, X2 error) {
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("EvalContext", map[string]interface{}{"parameters": parameters})
+		defer func() {
+			tracer.OnExit("EvalContext", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
+
+	if this.evaluationStages == nil {
+		return nil, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if parameters != nil {
+		parameters = &sanitizedParameters{parameters}
+	} else {
+		parameters = DUMMY_PARAMETERS
+	}
+
+	return this.evaluateStageContext(ctx, this.evaluationStages, parameters)
+}
+
+/*
+evaluateStageContext mirrors evaluateStage, but threads [ctx] down through the
+recursion so that cancellation is observed before each nested stage and before
+the stage's own operator runs.
+*/
+func (this EvaluableExpression) evaluateStageContext(ctx context.Context, stage *evaluationStage, parameters Parameters) (X1 interface{}, X2 error) {
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("evaluateStageContext", map[string]interface{}{"stage": stage, "parameters": parameters})
+		defer func() {
+			tracer.OnExit("evaluateStageContext", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var left, right interface{}
+	var err error
+
+	if stage.leftStage != nil {
+		left, err = this.evaluateStageContext(ctx, stage.leftStage, parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stage.isShortCircuitable() {
+		switch stage.symbol {
+		case AND:
+			if left == false {
+				return false, nil
+			}
+		case OR:
+			if left == true {
+				return true, nil
+			}
+		case COALESCE:
+			if left != nil {
+				return left, nil
+			}
+
+		case TERNARY_TRUE:
+			if left == false {
+				right = shortCircuitHolder
+			}
+		case TERNARY_FALSE:
+			if left != nil {
+				right = shortCircuitHolder
+			}
+		}
+	}
+
+	if right != shortCircuitHolder && stage.rightStage != nil {
+		right, err = this.evaluateStageContext(ctx, stage.rightStage, parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if this.ChecksTypes {
+		if stage.typeCheck == nil {
+			err = this.typeCheck(stage.leftTypeCheck, left, stage.symbol, stage.typeErrorFormat)
+			if err != nil {
+				return nil, err
+			}
+
+			err = this.typeCheck(stage.rightTypeCheck, right, stage.symbol, stage.typeErrorFormat)
+			if err != nil {
+				return nil, err
+			}
+		} else if !stage.typeCheck(left, right) {
+			errorMsg := fmt.Sprintf(stage.typeErrorFormat, left, stage.symbol.String())
+			return nil, errors.New(errorMsg)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return this.runOperator(ctx, stage, left, right, parameters)
+}
+
+/*
+operatorResult carries a stage operator's return values across the goroutine
+runOperator uses to race the operator against [ctx].
+*/
+type operatorResult struct {
+	value interface{}
+	err   error
+}
+
+/*
+cheapOperatorSymbols are the stage symbols whose operator is always a small,
+non-blocking native computation - +, -, *, /, %, the six comparisons, and the
+short-circuit logical/coalesce/ternary operators. runOperator calls these
+directly rather than racing them against ctx, since there's nothing in them
+that could block long enough for cancellation to matter.
+*/
+var cheapOperatorSymbols = map[OperatorSymbol]bool{
+	PLUS: true, MINUS: true, MULTIPLY: true, DIVIDE: true, MODULUS: true,
+	LT: true, LTE: true, GT: true, GTE: true, EQ: true, NEQ: true,
+	AND: true, OR: true, COALESCE: true, TERNARY_TRUE: true, TERNARY_FALSE: true,
+}
+
+/*
+runOperator calls stage.operator directly when it's known to be cheap
+(cheapOperatorSymbols) or when [ctx] can never be cancelled (ctx.Done() == nil,
+true of the context.Background() evaluateStage uses for plain Eval) - the
+common case, and the one chunk0-1's Program.Run hot loop depends on staying
+free of goroutine/channel overhead.
+
+Otherwise - any stage whose symbol is not in cheapOperatorSymbols, running
+under a ctx that can actually be done: a FUNCTION call or custom Language
+operator, potentially a user's ExpressionFunction doing I/O, but equally a
+literal, a variable lookup, or an unlisted operator such as ** -
+it runs stage.operator on its own goroutine and returns as soon as either it
+finishes or ctx is done, whichever comes first. This is what makes
+EvalContext's cancellation bound wall-clock time even against a function that
+doesn't cooperate with ctx itself; the ctx.Err() checks elsewhere in
+evaluateStageContext only catch cancellation *between* stages, never partway
+through one that's blocking.
+
+If ctx is done first, runOperator returns ctx.Err() immediately without
+waiting for the operator to finish; the goroutine keeps running until the
+operator returns on its own and then exits silently (the result channel is
+buffered so it never blocks on an abandoned receiver). A stage.operator that
+never returns - because it's wrapping a function with no cancellation
+awareness of its own - leaks that one goroutine for the life of the process.
+Functions that support real cancellation should use
+ExpressionFunctionWithContext via AdaptContextFunction instead of relying on
+this as a backstop.
+*/
+func (this EvaluableExpression) runOperator(ctx context.Context, stage *evaluationStage, left, right interface{}, parameters Parameters) (interface{}, error) {
+	if cheapOperatorSymbols[stage.symbol] || ctx.Done() == nil {
+		return stage.operator(left, right, parameters)
+	}
+
+	done := make(chan operatorResult, 1)
+
+	go func() {
+		value, err := stage.operator(left, right, parameters)
+		done <- operatorResult{value, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-done:
+		return result.value, result.err
+	}
+}
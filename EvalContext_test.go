@@ -0,0 +1,145 @@
+package govaluate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEvalContextCancelledBeforeStart(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = expr.EvalContext(ctx, nil)
+	if err != ctx.Err() {
+		t.Fatalf("expected ctx.Err(), got %v", err)
+	}
+}
+
+func TestEvalContextCancelledDuringBlockingFunction(t *testing.T) {
+	functions := map[string]ExpressionFunction{
+		"slow": func(arguments ...interface{}) (interface{}, error) {
+			time.Sleep(time.Second)
+			return 1.0, nil
+		},
+	}
+
+	expr, err := NewEvaluableExpressionWithFunctions("slow()", functions)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = expr.EvalContext(ctx, nil)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("expected EvalContext to return promptly on deadline, took %s", elapsed)
+	}
+}
+
+func TestEvalContextArithmeticDoesNotBlockOnBackgroundContext(t *testing.T) {
+	expr, err := NewEvaluableExpression("(1 + 2) * 3 - 4 / 2")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.EvalContext(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != 7.0 {
+		t.Fatalf("expected 7.0, got %v", result)
+	}
+}
+
+func TestCheapOperatorSymbolsSkipGoroutineRace(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result, err := expr.runOperator(ctx, expr.evaluationStages, 1.0, 1.0, DUMMY_PARAMETERS)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != 2.0 {
+		t.Fatalf("expected 2.0, got %v", result)
+	}
+}
+
+type evalContextRecordingTracer struct {
+	entered []string
+}
+
+func (this *evalContextRecordingTracer) OnEnter(name string, args map[string]interface{}) {
+	this.entered = append(this.entered, name)
+}
+
+func (this *evalContextRecordingTracer) OnExit(name string, results map[string]interface{}, err error) {
+}
+
+func TestEvalContextTypeCheckUsesInstanceTracerNotDefaultTracer(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + true")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	tracer := &evalContextRecordingTracer{}
+	expr.SetTracer(tracer)
+
+	if _, err := expr.EvalContext(context.Background(), nil); err == nil {
+		t.Fatal("expected a type-check error for mismatched operands")
+	}
+
+	found := false
+	for _, name := range tracer.entered {
+		if name == "typeCheck" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the tracer set via SetTracer to observe a typeCheck call, got %v", tracer.entered)
+	}
+}
+
+func TestAdaptContextFunctionReceivesBoundContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "key", "value")
+
+	received := func(c context.Context, arguments ...interface{}) (interface{}, error) {
+		return c.Value("key"), nil
+	}
+
+	functions := map[string]ExpressionFunction{
+		"lookup": AdaptContextFunction(ctx, received),
+	}
+
+	expr, err := NewEvaluableExpressionWithFunctions("lookup()", functions)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.EvalContext(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != "value" {
+		t.Fatalf("expected 'value', got %v", result)
+	}
+}
@@ -1,10 +1,9 @@
 package govaluate
 
 import (
+	"context"
 	"errors"
 	"fmt"
-
-	"github.com/wilebeast/govaluate/ellen"
 )
 
 const isoDateFormat string = "2006-01-02T15:04:05.999999999Z0700"
@@ -34,33 +33,56 @@ type EvaluableExpression struct {
 	*/
 	ChecksTypes bool
 
+	/*
+		The NumericMode most recently applied with WithNumericMode; Float64 by default.
+		Only WithNumericMode re-plans the stages - assigning this field directly does
+		not change how the expression evaluates. See NumericMode for the alternatives.
+	*/
+	Mode NumericMode
+
 	tokens           []ExpressionToken
 	evaluationStages *evaluationStage
 	inputExpression  string
+	tracer           Tracer
+
+	// originalStages caches the stage tree exactly as planStages built it, the
+	// first time WithNumericMode is called. Every WithNumericMode call clones
+	// this pristine tree fresh rather than mutating evaluationStages in place,
+	// so switching modes never shares or leaks state across instances. See
+	// NumericMode.go.
+	originalStages *evaluationStage
 }
 
 /*
 Parses a new EvaluableExpression from the given [expression] string.
 Returns an error if the given expression has invalid syntax.
 */
-func NewEvaluableExpression(expression string) (X1 *EvaluableExpression, X2 error) {
-	defer func() {
-		ellen.Printf("NewEvaluableExpression", map[string]interface{}{"expression": expression}, map[string]interface{}{"X1": X1, "X2": X2})
-
-		/*
-			Similar to [NewEvaluableExpression], except that instead of a string, an already-tokenized expression is given.
-			This is useful in cases where you may be generating an expression automatically, or using some other parser (e.g., to parse from a query language)
-		*/
-	}()
+func NewEvaluableExpression(expression string) (*EvaluableExpression, error) {
+	if DefaultTracer != nil {
+		DefaultTracer.OnEnter("NewEvaluableExpression", map[string]interface{}{"expression": expression})
+	}
 
 	functions := make(map[string]ExpressionFunction)
-	return NewEvaluableExpressionWithFunctions(expression, functions)
+	ret, err := NewEvaluableExpressionWithFunctions(expression, functions)
+
+	if DefaultTracer != nil {
+		DefaultTracer.OnExit("NewEvaluableExpression", map[string]interface{}{"X1": ret, "X2": err}, err)
+	}
+
+	return ret, err
 }
 
+/*
+Similar to [NewEvaluableExpression], except that instead of a string, an already-tokenized expression is given.
+This is useful in cases where you may be generating an expression automatically, or using some other parser (e.g., to parse from a query language)
+*/
 func NewEvaluableExpressionFromTokens(tokens []ExpressionToken) (X1 *EvaluableExpression, X2 error) {
-	defer func() {
-		ellen.Printf("NewEvaluableExpressionFromTokens", map[string]interface{}{"tokens": tokens}, map[string]interface{}{"X1": X1, "X2": X2})
-	}()
+	if DefaultTracer != nil {
+		DefaultTracer.OnEnter("NewEvaluableExpressionFromTokens", map[string]interface{}{"tokens": tokens})
+		defer func() {
+			DefaultTracer.OnExit("NewEvaluableExpressionFromTokens", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
 
 	var ret *EvaluableExpression
 	var err error
@@ -97,9 +119,12 @@ Similar to [NewEvaluableExpression], except enables the use of user-defined func
 Functions passed into this will be available to the expression.
 */
 func NewEvaluableExpressionWithFunctions(expression string, functions map[string]ExpressionFunction) (X1 *EvaluableExpression, X2 error) {
-	defer func() {
-		ellen.Printf("NewEvaluableExpressionWithFunctions", map[string]interface{}{"expression": expression, "functions": functions}, map[string]interface{}{"X1": X1, "X2": X2})
-	}()
+	if DefaultTracer != nil {
+		DefaultTracer.OnEnter("NewEvaluableExpressionWithFunctions", map[string]interface{}{"expression": expression, "functions": functions})
+		defer func() {
+			DefaultTracer.OnExit("NewEvaluableExpressionWithFunctions", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
 
 	var ret *EvaluableExpression
 	var err error
@@ -138,24 +163,17 @@ func NewEvaluableExpressionWithFunctions(expression string, functions map[string
 }
 
 /*
-Same as `Eval`, but automatically wraps a map of parameters into a `govalute.Parameters` structure.
+Runs the entire expression using the given [parameters], the same as Eval, but
+automatically wraps a map of parameters into a `govaluate.Parameters` structure.
 */
 func (this EvaluableExpression) Evaluate(parameters map[string]interface{}) (X1 interface{}, X2 error) {
-	defer func() {
-		ellen.Printf("Evaluate", map[string]interface{}{"parameters": parameters}, map[string]interface{}{"X1": X1, "X2": X2})
-
-		/*
-			Runs the entire expression using the given [parameters].
-			e.g., If the expression contains a reference to the variable "foo", it will be taken from `parameters.Get("foo")`.
-
-			This function returns errors if the combination of expression and parameters cannot be run,
-			such as if a variable in the expression is not present in [parameters].
-
-			In all non-error circumstances, this returns the single value result of the expression and parameters given.
-			e.g., if the expression is "1 + 1", this will return 2.0.
-			e.g., if the expression is "foo + 1" and parameters contains "foo" = 2, this will return 3.0
-		*/
-	}()
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Evaluate", map[string]interface{}{"parameters": parameters})
+		defer func() {
+			tracer.OnExit("Evaluate", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
 
 	if parameters == nil {
 		return this.Eval(nil)
@@ -164,10 +182,25 @@ func (this EvaluableExpression) Evaluate(parameters map[string]interface{}) (X1
 	return this.Eval(MapParameters(parameters))
 }
 
+/*
+Runs the entire expression using the given [parameters].
+e.g., If the expression contains a reference to the variable "foo", it will be taken from `parameters.Get("foo")`.
+
+This function returns errors if the combination of expression and parameters cannot be run,
+such as if a variable in the expression is not present in [parameters].
+
+In all non-error circumstances, this returns the single value result of the expression and parameters given.
+e.g., if the expression is "1 + 1", this will return 2.0.
+e.g., if the expression is "foo + 1" and parameters contains "foo" = 2, this will return 3.0
+*/
 func (this EvaluableExpression) Eval(parameters Parameters) (X1 interface{}, X2 error) {
-	defer func() {
-		ellen.Printf("Eval", map[string]interface{}{"parameters": parameters}, map[string]interface{}{"X1": X1, "X2": X2})
-	}()
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Eval", map[string]interface{}{"parameters": parameters})
+		defer func() {
+			tracer.OnExit("Eval", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
 
 	if this.evaluationStages == nil {
 		return nil, nil
@@ -182,82 +215,24 @@ func (this EvaluableExpression) Eval(parameters Parameters) (X1 interface{}, X2
 	return this.evaluateStage(this.evaluationStages, parameters)
 }
 
-func (this EvaluableExpression) evaluateStage(stage *evaluationStage, parameters Parameters) (X1 interface{}, X2 error) {
-	defer func() {
-		ellen.Printf("evaluateStage", map[string]interface{}{"stage": stage, "parameters": parameters}, map[string]interface{}{"X1": X1, "X2": X2})
-	}()
-
-	var left, right interface{}
-	var err error
-
-	if stage.leftStage != nil {
-		left, err = this.evaluateStage(stage.leftStage, parameters)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if stage.isShortCircuitable() {
-		switch stage.symbol {
-		case AND:
-			if left == false {
-				return false, nil
-			}
-		case OR:
-			if left == true {
-				return true, nil
-			}
-		case COALESCE:
-			if left != nil {
-				return left, nil
-			}
-
-		case TERNARY_TRUE:
-			if left == false {
-				right = shortCircuitHolder
-			}
-		case TERNARY_FALSE:
-			if left != nil {
-				right = shortCircuitHolder
-			}
-		}
-	}
-
-	if right != shortCircuitHolder && stage.rightStage != nil {
-		right, err = this.evaluateStage(stage.rightStage, parameters)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if this.ChecksTypes {
-		if stage.typeCheck == nil {
-
-			err = typeCheck(stage.leftTypeCheck, left, stage.symbol, stage.typeErrorFormat)
-			if err != nil {
-				return nil, err
-			}
-
-			err = typeCheck(stage.rightTypeCheck, right, stage.symbol, stage.typeErrorFormat)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// special case where the type check needs to know both sides to determine if the operator can handle it
-			if !stage.typeCheck(left, right) {
-				errorMsg := fmt.Sprintf(stage.typeErrorFormat, left, stage.symbol.String())
-				return nil, errors.New(errorMsg)
-			}
-		}
-	}
-
-	return stage.operator(left, right, parameters)
+/*
+evaluateStage is the non-context entry point to stage evaluation, used by Eval.
+It delegates to evaluateStageContext with a context.Background() that can never
+be cancelled, so Eval and EvalContext share one recursive implementation
+instead of maintaining two copies of the same traversal.
+*/
+func (this EvaluableExpression) evaluateStage(stage *evaluationStage, parameters Parameters) (interface{}, error) {
+	return this.evaluateStageContext(context.Background(), stage, parameters)
 }
 
-func typeCheck(check stageTypeCheck, value interface{}, symbol OperatorSymbol, format string) (X1 error) {
-	defer func() {
-		ellen.Printf("typeCheck", map[string]interface{}{"check": check, "value": value, "symbol": symbol, "format": format}, map[string]interface{}{"X1": X1})
-	}()
+func (this EvaluableExpression) typeCheck(check stageTypeCheck, value interface{}, symbol OperatorSymbol, format string) (X1 error) {
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("typeCheck", map[string]interface{}{"check": check, "value": value, "symbol": symbol, "format": format})
+		defer func() {
+			tracer.OnExit("typeCheck", map[string]interface{}{"X1": X1}, X1)
+		}()
+	}
 
 	if check == nil {
 		return nil
@@ -275,33 +250,44 @@ func typeCheck(check stageTypeCheck, value interface{}, symbol OperatorSymbol, f
 Returns an array representing the ExpressionTokens that make up this expression.
 */
 func (this EvaluableExpression) Tokens() (X1 []ExpressionToken) {
-	defer func() {
-		ellen.Printf("Tokens", map[string]interface{}{}, map[string]interface{}{"X1": X1})
-
-		/*
-			Returns the original expression used to create this EvaluableExpression.
-		*/
-	}()
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Tokens", map[string]interface{}{})
+		defer func() {
+			tracer.OnExit("Tokens", map[string]interface{}{"X1": X1}, nil)
+		}()
+	}
 
 	return this.tokens
 }
 
+/*
+Returns the original expression used to create this EvaluableExpression.
+*/
 func (this EvaluableExpression) String() (X1 string) {
-	defer func() {
-		ellen.Printf("String", map[string]interface{}{}, map[string]interface{}{"X1": X1})
-
-		/*
-			Returns an array representing the variables contained in this EvaluableExpression.
-		*/
-	}()
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("String", map[string]interface{}{})
+		defer func() {
+			tracer.OnExit("String", map[string]interface{}{"X1": X1}, nil)
+		}()
+	}
 
 	return this.inputExpression
 }
 
+/*
+Returns an array representing the variables contained in this EvaluableExpression.
+*/
 func (this EvaluableExpression) Vars() (X1 []string) {
-	defer func() {
-		ellen.Printf("Vars", map[string]interface{}{}, map[string]interface{}{"X1": X1})
-	}()
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Vars", map[string]interface{}{})
+		defer func() {
+			tracer.OnExit("Vars", map[string]interface{}{"X1": X1}, nil)
+		}()
+	}
+
 	var varlist []string
 	for _, val := range this.Tokens() {
 		if val.Kind == VARIABLE {
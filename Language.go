@@ -0,0 +1,379 @@
+package govaluate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+literalParser attempts to parse a literal value starting at the current position
+of a lexer stream. Implementations return found=false (with no error) when the
+input does not match their literal form, so that Language can try the next parser
+in line.
+*/
+type literalParser func(stream string, position int) (value interface{}, consumed int, found bool, err error)
+
+/*
+stageCombinedTypeCheck validates a binary operator's two operands together,
+the same two-operand shape stage.typeCheck already uses in
+EvaluableExpression.go's evaluateStageContext (as opposed to stageTypeCheck,
+which validates one operand at a time and is what Program.go's
+leftTypeCheck/rightTypeCheck handling expects).
+*/
+type stageCombinedTypeCheck func(left, right interface{}) bool
+
+/*
+Operator describes a single custom binary infix operator contributed to a
+Language, along with the precedence it should bind at and the stage operator
+function used to evaluate it once both operands are resolved. Lower
+Precedence values bind looser and are split first, the same convention
+arithmetic's "+ splits before *" follows.
+*/
+type Operator struct {
+	Symbol     string
+	Precedence int
+	Operator   stageOperator
+	TypeCheck  stageCombinedTypeCheck
+}
+
+/*
+Language bundles a set of custom operators, literal parsers, and functions that
+together define a dialect of the expression grammar, analogous to gval's
+gval.Language. NewEvaluableExpressionWithLanguage preprocesses an expression's
+text against a Language before handing it to the built-in tokenizer/planner in
+parsing.go (not present in this tree), rather than needing to extend those
+directly. This lets callers compose dialects - SQL-like LIKE/BETWEEN, CEL-like
+has()/matches(), or a time-aware grammar - without forking the parser.
+
+Custom literals are recognized by trying every registered literalParser, in
+registration order, against each position of the expression text outside of
+quoted strings; a match is replaced with a call to a synthesized zero-argument
+function that returns the parsed value. Custom operators are recognized by
+scanning the text for each registered Symbol outside of quotes and
+parentheses, splitting at the lowest-precedence (rightmost, for ties)
+occurrence first exactly as a precedence-climbing parser would, and replacing
+each split with a call to a synthesized two-argument function wrapping that
+Operator's stage operator and type check. Both rewrites happen before the
+built-in tokenizer ever sees the expression, so the result is ordinary
+govaluate syntax by the time parseTokens runs.
+*/
+type Language struct {
+	operators map[string]Operator
+	literals  []literalParser
+	functions map[string]ExpressionFunction
+}
+
+/*
+NewLanguage constructs an empty Language with no operators, literals, or
+functions registered. Use the With* methods to build up a dialect before passing
+it to NewEvaluableExpressionWithLanguage.
+*/
+func NewLanguage() *Language {
+	return &Language{
+		operators: make(map[string]Operator),
+		functions: make(map[string]ExpressionFunction),
+	}
+}
+
+/*
+WithOperator registers [op] under its Symbol, returning the same Language so
+calls can be chained. Registering a symbol that collides with a built-in
+operator's text (e.g. "+") overrides the built-in for expressions parsed with
+this Language.
+*/
+func (this *Language) WithOperator(op Operator) *Language {
+	this.operators[op.Symbol] = op
+	return this
+}
+
+/*
+WithLiteral registers a custom literal parser, tried in registration order before
+falling back to the built-in numeric, string, and boolean literal parsers.
+*/
+func (this *Language) WithLiteral(parser literalParser) *Language {
+	this.literals = append(this.literals, parser)
+	return this
+}
+
+/*
+WithFunction registers a named function callable from expressions parsed with
+this Language, the same as the `functions` map passed to
+NewEvaluableExpressionWithFunctions.
+*/
+func (this *Language) WithFunction(name string, function ExpressionFunction) *Language {
+	this.functions[name] = function
+	return this
+}
+
+/*
+NewEvaluableExpressionWithLanguage parses [expression] using the operators,
+literals, and functions registered on [lang] in addition to the built-in
+grammar.
+*/
+func NewEvaluableExpressionWithLanguage(expression string, lang *Language) (X1 *EvaluableExpression, X2 error) {
+	if DefaultTracer != nil {
+		DefaultTracer.OnEnter("NewEvaluableExpressionWithLanguage", map[string]interface{}{"expression": expression, "lang": lang})
+		defer func() {
+			DefaultTracer.OnExit("NewEvaluableExpressionWithLanguage", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
+
+	if lang == nil {
+		return nil, errors.New("a non-nil Language must be given")
+	}
+
+	rewritten, functions, err := rewriteLanguageExtensions(expression, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEvaluableExpressionWithFunctions(rewritten, functions)
+}
+
+/*
+rewriteLanguageExtensions applies lang's custom literals and then its custom
+operators to [expression]'s text, returning the rewritten expression alongside
+a functions map merging lang.functions with every synthesized function the two
+rewrites introduced.
+*/
+func rewriteLanguageExtensions(expression string, lang *Language) (string, map[string]ExpressionFunction, error) {
+	functions := make(map[string]ExpressionFunction, len(lang.functions))
+	for name, fn := range lang.functions {
+		functions[name] = fn
+	}
+
+	expression, err := rewriteCustomLiterals(expression, lang.literals, functions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rewriter := &operatorRewriter{
+		operators: lang.operators,
+		functions: functions,
+		symbols:   sortedOperatorSymbols(lang.operators),
+	}
+
+	expression, err = rewriter.rewrite(expression)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return expression, functions, nil
+}
+
+/*
+rewriteCustomLiterals replaces every match of a [literals] parser found in
+[expression]'s text (outside of quoted strings) with a call to a synthesized
+zero-argument function added to [functions] that returns the parsed value.
+*/
+func rewriteCustomLiterals(expression string, literals []literalParser, functions map[string]ExpressionFunction) (string, error) {
+	if len(literals) == 0 {
+		return expression, nil
+	}
+
+	var out strings.Builder
+	var inQuote byte
+	counter := 0
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+
+		if inQuote != 0 {
+			out.WriteByte(c)
+			if c == inQuote && expression[i-1] != '\\' {
+				inQuote = 0
+			}
+			i++
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			inQuote = c
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		matched := false
+		for _, parse := range literals {
+			value, consumed, found, err := parse(expression, i)
+			if err != nil {
+				return "", err
+			}
+			if !found || consumed <= 0 {
+				continue
+			}
+
+			name := fmt.Sprintf("__govaluate_literal_%d__", counter)
+			counter++
+			captured := value
+			functions[name] = func(arguments ...interface{}) (interface{}, error) {
+				return captured, nil
+			}
+
+			out.WriteString(name)
+			out.WriteString("()")
+			i += consumed
+			matched = true
+			break
+		}
+
+		if matched {
+			continue
+		}
+
+		out.WriteByte(c)
+		i++
+	}
+
+	return out.String(), nil
+}
+
+/*
+operatorRewriter recursively splits an expression's text at its registered
+custom operators, lowest precedence first, turning each split into a call to a
+synthesized two-argument function.
+*/
+type operatorRewriter struct {
+	operators map[string]Operator
+	functions map[string]ExpressionFunction
+	symbols   []string
+	counter   int
+}
+
+func (this *operatorRewriter) rewrite(expr string) (string, error) {
+	if len(this.operators) == 0 {
+		return expr, nil
+	}
+
+	trimmed := strings.TrimSpace(expr)
+
+	symbol, pos, op, found := this.findSplitPoint(trimmed)
+	if !found {
+		return trimmed, nil
+	}
+
+	left, err := this.rewrite(trimmed[:pos])
+	if err != nil {
+		return "", err
+	}
+
+	right, err := this.rewrite(trimmed[pos+len(symbol):])
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("__govaluate_operator_%d__", this.counter)
+	this.counter++
+	this.functions[name] = makeCustomOperatorFunction(op)
+
+	return fmt.Sprintf("%s(%s, %s)", name, left, right), nil
+}
+
+/*
+findSplitPoint scans [expr] for top-level (outside quotes and parentheses)
+occurrences of any registered operator symbol and returns the one with the
+lowest precedence, preferring the rightmost occurrence on a precedence tie so
+that repeated same-precedence operators associate left, e.g. `a - b - c`
+splits into `(a - b) - c` rather than `a - (b - c)`.
+*/
+func (this *operatorRewriter) findSplitPoint(expr string) (symbol string, pos int, op Operator, found bool) {
+	depth := 0
+	var inQuote byte
+	bestPrecedence := 0
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+
+		if inQuote != 0 {
+			if c == inQuote && expr[i-1] != '\\' {
+				inQuote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			inQuote = c
+			continue
+		case '(':
+			depth++
+			continue
+		case ')':
+			depth--
+			continue
+		}
+
+		if depth != 0 {
+			continue
+		}
+
+		for _, sym := range this.symbols {
+			if !strings.HasPrefix(expr[i:], sym) {
+				continue
+			}
+			if isWordOperatorSymbol(sym) {
+				if i > 0 && isIdentPart(expr[i-1]) {
+					continue
+				}
+				end := i + len(sym)
+				if end < len(expr) && isIdentPart(expr[end]) {
+					continue
+				}
+			}
+
+			candidate := this.operators[sym]
+			if !found || candidate.Precedence <= bestPrecedence {
+				symbol, pos, op, found = sym, i, candidate, true
+				bestPrecedence = candidate.Precedence
+			}
+			break
+		}
+	}
+
+	return
+}
+
+/*
+sortedOperatorSymbols returns [operators]' keys sorted longest-first, so that
+findSplitPoint prefers the longest matching symbol when two registered
+symbols share a prefix at the same position (e.g. "<" and "<=").
+*/
+func sortedOperatorSymbols(operators map[string]Operator) []string {
+	symbols := make([]string, 0, len(operators))
+	for symbol := range operators {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		return len(symbols[i]) > len(symbols[j])
+	})
+
+	return symbols
+}
+
+func isWordOperatorSymbol(symbol string) bool {
+	return symbol != "" && isIdentPart(symbol[0])
+}
+
+/*
+makeCustomOperatorFunction adapts [op] into the two-argument ExpressionFunction
+form that a rewritten operator split is replaced with.
+*/
+func makeCustomOperatorFunction(op Operator) ExpressionFunction {
+	return func(arguments ...interface{}) (interface{}, error) {
+		if len(arguments) != 2 {
+			return nil, errors.New(fmt.Sprintf("operator '%s' expects exactly two operands, got %d", op.Symbol, len(arguments)))
+		}
+
+		left, right := arguments[0], arguments[1]
+
+		if op.TypeCheck != nil && !op.TypeCheck(left, right) {
+			return nil, errors.New(fmt.Sprintf("operator '%s' does not accept operands %v, %v", op.Symbol, left, right))
+		}
+
+		return op.Operator(left, right, DUMMY_PARAMETERS)
+	}
+}
@@ -0,0 +1,151 @@
+package govaluate
+
+import (
+	"strconv"
+	"testing"
+)
+
+func hexLiteralParser(stream string, position int) (interface{}, int, bool, error) {
+	if position+1 >= len(stream) || stream[position] != '0' || stream[position+1] != 'x' {
+		return nil, 0, false, nil
+	}
+
+	end := position + 2
+	for end < len(stream) && isHexDigit(stream[end]) {
+		end++
+	}
+	if end == position+2 {
+		return nil, 0, false, nil
+	}
+
+	value, err := strconv.ParseInt(stream[position+2:end], 16, 64)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return float64(value), end - position, true, nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func TestLanguageCustomLiteral(t *testing.T) {
+	lang := NewLanguage().WithLiteral(hexLiteralParser)
+
+	expr, err := NewEvaluableExpressionWithLanguage("0x10 + 1", lang)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != 17.0 {
+		t.Fatalf("expected 17.0, got %v", result)
+	}
+}
+
+func TestLanguageCustomOperatorPrecedence(t *testing.T) {
+	lang := NewLanguage().WithOperator(Operator{
+		Symbol:     "CONCAT",
+		Precedence: 1,
+		Operator: func(left, right interface{}, parameters Parameters) (interface{}, error) {
+			return left.(float64) + right.(float64)*10, nil
+		},
+	})
+
+	expr, err := NewEvaluableExpressionWithLanguage("1 CONCAT 2 CONCAT 3", lang)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	// left-associative: (1 CONCAT 2) CONCAT 3 == (1 + 20) CONCAT 3 == 21 + 30 == 51
+	if result != 51.0 {
+		t.Fatalf("expected 51.0, got %v", result)
+	}
+}
+
+func TestLanguageCustomOperatorRespectsParentheses(t *testing.T) {
+	lang := NewLanguage().WithOperator(Operator{
+		Symbol:     "AND2",
+		Precedence: 1,
+		Operator: func(left, right interface{}, parameters Parameters) (interface{}, error) {
+			return left.(bool) && right.(bool), nil
+		},
+	})
+
+	expr, err := NewEvaluableExpressionWithLanguage("(1 == 1 AND2 2 == 2)", lang)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != true {
+		t.Fatalf("expected true, got %v", result)
+	}
+}
+
+func TestLanguageCustomOperatorTypeCheckRejectsOperands(t *testing.T) {
+	lang := NewLanguage().WithOperator(Operator{
+		Symbol:     "CONCAT",
+		Precedence: 1,
+		TypeCheck: func(left, right interface{}) bool {
+			_, leftOK := left.(float64)
+			_, rightOK := right.(float64)
+			return leftOK && rightOK
+		},
+		Operator: func(left, right interface{}, parameters Parameters) (interface{}, error) {
+			return left.(float64) + right.(float64), nil
+		},
+	})
+
+	expr, err := NewEvaluableExpressionWithLanguage(`1 CONCAT "two"`, lang)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	if _, err := expr.Evaluate(nil); err == nil {
+		t.Fatal("expected TypeCheck to reject a string operand")
+	}
+}
+
+func TestLanguageCustomOperatorTypeCheckAcceptsOperands(t *testing.T) {
+	lang := NewLanguage().WithOperator(Operator{
+		Symbol:     "CONCAT",
+		Precedence: 1,
+		TypeCheck: func(left, right interface{}) bool {
+			_, leftOK := left.(float64)
+			_, rightOK := right.(float64)
+			return leftOK && rightOK
+		},
+		Operator: func(left, right interface{}, parameters Parameters) (interface{}, error) {
+			return left.(float64) + right.(float64), nil
+		},
+	})
+
+	expr, err := NewEvaluableExpressionWithLanguage("1 CONCAT 2", lang)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate expression: %s", err)
+	}
+
+	if result != 3.0 {
+		t.Fatalf("expected 3.0, got %v", result)
+	}
+}
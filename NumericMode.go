@@ -0,0 +1,259 @@
+package govaluate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+/*
+NumericMode selects the implementation of the arithmetic and comparison
+operators applied to numeric values. Numeric literals are parsed as float64 in
+every mode (see BigFloat). It defaults to Float64, which matches the library's
+historical behavior.
+*/
+type NumericMode int
+
+const (
+	// Float64 parses numeric literals as float64 and evaluates arithmetic with
+	// native float64 operators, the same as every release before NumericMode existed.
+	Float64 NumericMode = iota
+
+	// BigFloat evaluates arithmetic with arbitrary-precision big.Float operators
+	// instead of native float64 ones. Note that this only eliminates rounding
+	// error introduced by arithmetic on values that already hold full precision
+	// (e.g. parameters sourced from a decimal string or database numeric column);
+	// numeric literals in the expression text itself are still tokenized as
+	// float64 before WithNumericMode ever runs, so the literals in `0.1 + 0.2`
+	// keep float64's rounding error and their sum is not 0.3.
+	BigFloat
+
+	// Decimal applies no decimal arithmetic: WithNumericMode(Decimal) clones
+	// the stage tree the same way Float64 does, so the expression evaluates
+	// with plain float64 arithmetic, exactly as in Float64 mode.
+	Decimal
+)
+
+/*
+bigFloatPrecision is the mantissa precision, in bits, given to every big.Float
+produced while operating in BigFloat mode. 200 bits comfortably exceeds
+float64's 53 bits without making every operation prohibitively slow.
+*/
+const bigFloatPrecision uint = 200
+
+/*
+numericOperand normalizes a value coming from a literal or a parameter lookup
+into a *big.Float, so that BigFloat-mode arithmetic stages have a single type
+to operate on regardless of whether the value originated as a float64, an int,
+or an already-parsed *big.Float.
+*/
+func numericOperand(value interface{}) (*big.Float, error) {
+	switch v := value.(type) {
+	case *big.Float:
+		return v, nil
+	case float64:
+		return new(big.Float).SetPrec(bigFloatPrecision).SetFloat64(v), nil
+	case int:
+		return new(big.Float).SetPrec(bigFloatPrecision).SetInt64(int64(v)), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("value '%v' is not numeric", value))
+	}
+}
+
+/*
+bigFloatAdd, bigFloatSubtract, bigFloatMultiply, and bigFloatDivide are the
+BigFloat-mode counterparts of the float64 arithmetic operators, used by stages
+planned while NumericMode is BigFloat. Division by zero returns an error
+rather than the float64 +Inf/NaN convention, since accounting code generally
+wants that surfaced as a failure.
+*/
+func bigFloatAdd(left, right interface{}) (interface{}, error) {
+	return bigFloatOp(left, right, (*big.Float).Add)
+}
+
+func bigFloatSubtract(left, right interface{}) (interface{}, error) {
+	return bigFloatOp(left, right, (*big.Float).Sub)
+}
+
+func bigFloatMultiply(left, right interface{}) (interface{}, error) {
+	return bigFloatOp(left, right, (*big.Float).Mul)
+}
+
+func bigFloatDivide(left, right interface{}) (interface{}, error) {
+	l, err := numericOperand(left)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := numericOperand(right)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+
+	return new(big.Float).SetPrec(bigFloatPrecision).Quo(l, r), nil
+}
+
+/*
+bigFloatModulus computes left - right*trunc(left/right), the same truncating
+convention Go's native % operator uses for floats, via big.Float.Int to
+truncate the quotient toward zero.
+*/
+func bigFloatModulus(left, right interface{}) (interface{}, error) {
+	l, err := numericOperand(left)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := numericOperand(right)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Sign() == 0 {
+		return nil, errors.New("division by zero")
+	}
+
+	quotient := new(big.Float).SetPrec(bigFloatPrecision).Quo(l, r)
+	truncated, _ := quotient.Int(nil)
+	truncatedFloat := new(big.Float).SetPrec(bigFloatPrecision).SetInt(truncated)
+	product := new(big.Float).SetPrec(bigFloatPrecision).Mul(truncatedFloat, r)
+
+	return new(big.Float).SetPrec(bigFloatPrecision).Sub(l, product), nil
+}
+
+func bigFloatOp(left, right interface{}, op func(z, x, y *big.Float) *big.Float) (interface{}, error) {
+	l, err := numericOperand(left)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := numericOperand(right)
+	if err != nil {
+		return nil, err
+	}
+
+	return op(new(big.Float).SetPrec(bigFloatPrecision), l, r), nil
+}
+
+/*
+bigFloatCompare returns -1, 0, or 1 the same as (*big.Float).Cmp, after coercing
+both operands with numericOperand. Comparison stages (<, <=, >, >=, ==, !=)
+planned in BigFloat mode dispatch to this instead of native float64 comparison.
+*/
+func bigFloatCompare(left, right interface{}) (int, error) {
+	l, err := numericOperand(left)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := numericOperand(right)
+	if err != nil {
+		return 0, err
+	}
+
+	return l.Cmp(r), nil
+}
+
+func bigFloatComparison(left, right interface{}, test func(cmp int) bool) (interface{}, error) {
+	cmp, err := bigFloatCompare(left, right)
+	if err != nil {
+		return nil, err
+	}
+	return test(cmp), nil
+}
+
+/*
+bigFloatArithmeticOperators and bigFloatComparisonOperators map each
+OperatorSymbol handled in BigFloat mode to the operator function that should
+replace its float64 implementation.
+*/
+var bigFloatArithmeticOperators = map[OperatorSymbol]stageOperator{
+	PLUS:     func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatAdd(left, right) },
+	MINUS:    func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatSubtract(left, right) },
+	MULTIPLY: func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatMultiply(left, right) },
+	DIVIDE:   func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatDivide(left, right) },
+	MODULUS:  func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatModulus(left, right) },
+}
+
+var bigFloatComparisonOperators = map[OperatorSymbol]stageOperator{
+	LT:  func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c < 0 }) },
+	LTE: func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c <= 0 }) },
+	GT:  func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c > 0 }) },
+	GTE: func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c >= 0 }) },
+	EQ:  func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c == 0 }) },
+	NEQ: func(left, right interface{}, parameters Parameters) (interface{}, error) { return bigFloatComparison(left, right, func(c int) bool { return c != 0 }) },
+}
+
+/*
+WithNumericMode re-plans this expression's arithmetic and comparison stages to
+operate in [mode] and returns the same *EvaluableExpression so calls can be
+chained. It must be called before Eval; switching modes on an expression
+already mid-Eval has no defined behavior.
+
+The first call caches this.evaluationStages, untouched, as originalStages.
+Every call - this one included - then points this.evaluationStages at a fresh
+cloneEvaluationStage of that pristine tree before applying mode overrides to
+the clone. Two consequences follow: switching back to Float64 is simply
+cloning the pristine tree with no overrides applied, so there's no
+snapshot/restore bookkeeping to get wrong, and no stage is ever mutated that
+another EvaluableExpression or another clone still in use might be holding a
+reference to - unlike mutating stages in place via a shared package-level map,
+this has no cross-instance sharing and nothing to leak.
+
+Switching to BigFloat also clears the affected stages' float64 type checks,
+since ChecksTypes would otherwise reject the *big.Float operands those stages
+now produce and consume.
+*/
+func (this *EvaluableExpression) WithNumericMode(mode NumericMode) *EvaluableExpression {
+	if this.originalStages == nil {
+		this.originalStages = this.evaluationStages
+	}
+
+	this.Mode = mode
+	this.evaluationStages = cloneEvaluationStage(this.originalStages)
+	applyNumericModeOverrides(this.evaluationStages, mode)
+
+	return this
+}
+
+/*
+cloneEvaluationStage deep-copies [stage] and its entire leftStage/rightStage
+subtree, so that mutating the clone's operator or type checks can never affect
+the tree it was cloned from.
+*/
+func cloneEvaluationStage(stage *evaluationStage) *evaluationStage {
+	if stage == nil {
+		return nil
+	}
+
+	clone := *stage
+	clone.leftStage = cloneEvaluationStage(stage.leftStage)
+	clone.rightStage = cloneEvaluationStage(stage.rightStage)
+
+	return &clone
+}
+
+func applyNumericModeOverrides(stage *evaluationStage, mode NumericMode) {
+	if stage == nil || mode != BigFloat {
+		return
+	}
+
+	op, handled := bigFloatArithmeticOperators[stage.symbol]
+	if !handled {
+		op, handled = bigFloatComparisonOperators[stage.symbol]
+	}
+
+	if handled {
+		stage.operator = op
+		stage.typeCheck = nil
+		stage.leftTypeCheck = nil
+		stage.rightTypeCheck = nil
+	}
+
+	applyNumericModeOverrides(stage.leftStage, mode)
+	applyNumericModeOverrides(stage.rightStage, mode)
+}
@@ -0,0 +1,135 @@
+package govaluate
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWithNumericModeBigFloatDoesNotMutateOriginalStages(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	expr.WithNumericMode(BigFloat)
+
+	if expr.originalStages == expr.evaluationStages {
+		t.Fatal("expected evaluationStages to be a clone, not the original tree, after WithNumericMode")
+	}
+	if expr.originalStages.operator == nil {
+		t.Fatal("expected originalStages to retain a valid operator")
+	}
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate in BigFloat mode: %s", err)
+	}
+
+	value, ok := result.(*big.Float)
+	if !ok {
+		t.Fatalf("expected *big.Float result in BigFloat mode, got %T", result)
+	}
+
+	if value.Cmp(big.NewFloat(2)) != 0 {
+		t.Fatalf("expected 2, got %v", value)
+	}
+}
+
+func TestWithNumericModeSwitchBackToFloat64(t *testing.T) {
+	expr, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	expr.WithNumericMode(BigFloat)
+	expr.WithNumericMode(Float64)
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate after switching back to Float64: %s", err)
+	}
+
+	if result != 2.0 {
+		t.Fatalf("expected 2.0, got %v (%T)", result, result)
+	}
+}
+
+func TestWithNumericModeTwoExpressionsDoNotShareState(t *testing.T) {
+	exprA, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+	exprB, err := NewEvaluableExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	exprA.WithNumericMode(BigFloat)
+
+	result, err := exprB.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate exprB: %s", err)
+	}
+
+	if result != 2.0 {
+		t.Fatalf("expected exprB to be unaffected by exprA's WithNumericMode call, got %v (%T)", result, result)
+	}
+}
+
+func TestWithNumericModeBigFloatDoesNotFixLiteralRoundingError(t *testing.T) {
+	expr, err := NewEvaluableExpression("0.1 + 0.2")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	expr.WithNumericMode(BigFloat)
+
+	result, err := expr.Evaluate(nil)
+	if err != nil {
+		t.Fatalf("failed to evaluate in BigFloat mode: %s", err)
+	}
+
+	value, ok := result.(*big.Float)
+	if !ok {
+		t.Fatalf("expected *big.Float result in BigFloat mode, got %T", result)
+	}
+
+	// As documented on the BigFloat constant: 0.1 and 0.2 are tokenized to
+	// float64 before WithNumericMode ever runs, so the literals already carry
+	// float64's rounding error by the time numericOperand promotes them to
+	// *big.Float. BigFloat arithmetic is exact from that point on, so the
+	// result is the *big.Float exactly equal to the float64 sum 0.1+0.2 -
+	// which is not exactly 0.3.
+	exact := new(big.Float).SetPrec(bigFloatPrecision).SetFloat64(0.1 + 0.2)
+	if value.Cmp(exact) != 0 {
+		t.Fatalf("expected the float64-rounded sum %v, got %v", exact, value)
+	}
+
+	threeTenths := new(big.Float).SetPrec(bigFloatPrecision).SetFloat64(0.3)
+	if value.Cmp(threeTenths) == 0 {
+		t.Fatal("expected BigFloat mode to NOT fix literal rounding error, but 0.1 + 0.2 == 0.3 exactly")
+	}
+}
+
+func TestBigFloatModulus(t *testing.T) {
+	result, err := bigFloatModulus(7.0, 3.0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	value, ok := result.(*big.Float)
+	if !ok {
+		t.Fatalf("expected *big.Float, got %T", result)
+	}
+
+	if value.Cmp(big.NewFloat(1)) != 0 {
+		t.Fatalf("expected 1, got %v", value)
+	}
+}
+
+func TestBigFloatModulusDivisionByZero(t *testing.T) {
+	_, err := bigFloatModulus(7.0, 0.0)
+	if err == nil {
+		t.Fatal("expected an error for modulus by zero")
+	}
+}
@@ -0,0 +1,291 @@
+package govaluate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+/*
+FunctionSignature describes the expected input and output kinds of a function made
+available to a compiled expression, so that Compile can check call sites without
+running them.
+*/
+type FunctionSignature struct {
+	Inputs []reflect.Kind
+	Output reflect.Kind
+}
+
+/*
+Environment declares the variables and functions that a compiled expression is
+allowed to reference, along with the Go kind each one must produce. Compile uses
+this to fail fast on unknown symbols or type mismatches, rather than discovering
+them at Eval time.
+*/
+type Environment struct {
+	Variables map[string]reflect.Kind
+	Functions map[string]FunctionSignature
+}
+
+/*
+Program is the result of compiling an EvaluableExpression against an Environment.
+It caches the planned evaluation stages alongside a flat, ordered list of the
+variable names the expression references, so that Run resolves each one once per
+call instead of once per occurrence.
+*/
+type Program struct {
+	expression *EvaluableExpression
+	slots      []string
+}
+
+/*
+Compile performs a static check of this expression against [env] and returns a
+reusable Program. Use this when an expression is parsed once (e.g. at config
+load) and then evaluated many times in a hot loop.
+
+Compile returns an error if the expression references a variable or function
+that is not declared in [env], if a function call's argument count doesn't
+match its declared FunctionSignature.Inputs, or if a declared variable's kind
+fails every type check anywhere in the expression's evaluation stages - e.g.
+declaring a variable as reflect.String and then using it as an operand to
+arithmetic. That last check is necessarily approximate: evaluationStage does
+not record which variable a given stage's operand came from, so Compile can
+only confirm the declared kind satisfies *some* type check it could plausibly
+reach, not pinpoint a specific misuse site. Program.Run keeps this
+expression's ChecksTypes setting, so with ChecksTypes on (the default) a
+mismatch that slips past this approximation is still caught - and returned as
+an error, not a panic - at run time.
+*/
+func (this EvaluableExpression) Compile(env Environment) (X1 *Program, X2 error) {
+	tracer := this.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Compile", map[string]interface{}{"env": env})
+		defer func() {
+			tracer.OnExit("Compile", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
+
+	program := &Program{expression: &this}
+	seen := make(map[string]bool)
+
+	for _, name := range this.Vars() {
+		kind, found := env.Variables[name]
+		if !found {
+			return nil, errors.New(fmt.Sprintf("undeclared variable '%s' referenced by expression", name))
+		}
+
+		if kind == reflect.Invalid {
+			return nil, errors.New(fmt.Sprintf("variable '%s' has no declared kind in environment", name))
+		}
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if !kindSatisfiesSomeStage(this.evaluationStages, kind) {
+			return nil, errors.New(fmt.Sprintf("variable '%s' is declared as %s, which no operator in the expression accepts", name, kind.String()))
+		}
+
+		program.slots = append(program.slots, name)
+	}
+
+	if err := checkFunctionSignatures(this.tokens, env.Functions); err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+/*
+kindSatisfiesSomeStage reports whether a zero value of [kind] passes at least
+one type check present anywhere in [stage]'s tree, or whether the tree has no
+type checks at all (in which case there's nothing to fail against). This is
+the closest Compile can get to validating a declared variable's kind without
+evaluationStage recording which variable feeds which operand. Numeric kinds
+are sampled as float64(0); kinds other than bool, string, and the numeric ones
+are accepted without a check.
+*/
+func kindSatisfiesSomeStage(stage *evaluationStage, kind reflect.Kind) bool {
+	if !treeHasAnyTypeCheck(stage) {
+		return true
+	}
+
+	var sample interface{}
+	switch kind {
+	case reflect.Bool:
+		sample = false
+	case reflect.String:
+		sample = ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		sample = 0.0
+	default:
+		return true
+	}
+
+	return treeHasAcceptingTypeCheck(stage, sample)
+}
+
+func treeHasAnyTypeCheck(stage *evaluationStage) bool {
+	if stage == nil {
+		return false
+	}
+
+	if stage.leftTypeCheck != nil || stage.rightTypeCheck != nil {
+		return true
+	}
+
+	return treeHasAnyTypeCheck(stage.leftStage) || treeHasAnyTypeCheck(stage.rightStage)
+}
+
+func treeHasAcceptingTypeCheck(stage *evaluationStage, sample interface{}) bool {
+	if stage == nil {
+		return false
+	}
+
+	for _, check := range []stageTypeCheck{stage.leftTypeCheck, stage.rightTypeCheck} {
+		if check != nil && check(sample) {
+			return true
+		}
+	}
+
+	return treeHasAcceptingTypeCheck(stage.leftStage, sample) || treeHasAcceptingTypeCheck(stage.rightStage, sample)
+}
+
+/*
+checkFunctionSignatures walks the token stream counting each function call's
+top-level argument list and comparing its length against the matching
+FunctionSignature.Inputs, so a call with the wrong arity is caught at Compile
+time rather than at the function itself panicking on a bad index.
+*/
+func checkFunctionSignatures(tokens []ExpressionToken, functions map[string]FunctionSignature) error {
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Kind != FUNCTION {
+			continue
+		}
+
+		name, ok := token.Value.(string)
+		if !ok {
+			continue
+		}
+
+		signature, found := functions[name]
+		if !found {
+			return errors.New(fmt.Sprintf("undeclared function '%s' referenced by expression", name))
+		}
+
+		if i+1 >= len(tokens) || tokens[i+1].Kind != CLAUSE {
+			continue
+		}
+
+		argCount, err := countCallArguments(tokens, i+2)
+		if err != nil {
+			return err
+		}
+
+		if argCount != len(signature.Inputs) {
+			return errors.New(fmt.Sprintf("function '%s' expects %d argument(s), call has %d", name, len(signature.Inputs), argCount))
+		}
+	}
+
+	return nil
+}
+
+/*
+countCallArguments counts the top-level (depth-0) arguments of a function call
+whose opening CLAUSE token has already been consumed, starting at [position].
+An empty argument list, i.e. `f()`, counts as zero arguments.
+*/
+func countCallArguments(tokens []ExpressionToken, position int) (int, error) {
+	depth := 0
+	count := 0
+	sawAnyToken := false
+
+	for ; position < len(tokens); position++ {
+		token := tokens[position]
+
+		switch token.Kind {
+		case CLAUSE:
+			depth++
+			sawAnyToken = true
+		case CLAUSE_CLOSE:
+			if depth == 0 {
+				if sawAnyToken {
+					count++
+				}
+				return count, nil
+			}
+			depth--
+			sawAnyToken = true
+		case SEPARATOR:
+			if depth == 0 {
+				count++
+				sawAnyToken = false
+				continue
+			}
+			sawAnyToken = true
+		default:
+			sawAnyToken = true
+		}
+	}
+
+	return 0, errors.New("unterminated function call argument list")
+}
+
+/*
+slotParameters adapts a flat vector of parameter values, ordered the same way
+as Program.slots, into something evaluateStage can consume as Parameters. Get
+resolves a name to its slot with a linear scan rather than a map: Program.slots
+holds one entry per distinct variable the expression references, which for a
+typical expression is a handful of names - cheaper to scan directly than to pay
+for hashing.
+*/
+type slotParameters struct {
+	program *Program
+	values  []interface{}
+}
+
+func (this *slotParameters) Get(name string) (interface{}, error) {
+	for i, slot := range this.program.slots {
+		if slot == name {
+			return this.values[i], nil
+		}
+	}
+
+	return nil, errors.New(fmt.Sprintf("no parameter '%s' supplied to compiled program", name))
+}
+
+/*
+Run evaluates the compiled Program against [params], a map keyed by the same
+variable names the Program was compiled with. Unlike Eval, Run looks each
+distinct variable up in [params] once regardless of how many times it's
+referenced in the expression, instead of hashing through params on every
+occurrence. ChecksTypes is left exactly as the source expression set it, so a
+type mismatch Compile's approximate check didn't catch still surfaces as an
+error from Eval, not a panic.
+*/
+func (this *Program) Run(params map[string]interface{}) (X1 interface{}, X2 error) {
+	tracer := this.expression.tracerFor()
+	if tracer != nil {
+		tracer.OnEnter("Run", map[string]interface{}{"params": params})
+		defer func() {
+			tracer.OnExit("Run", map[string]interface{}{"X1": X1, "X2": X2}, X2)
+		}()
+	}
+
+	values := make([]interface{}, len(this.slots))
+	for i, name := range this.slots {
+		value, found := params[name]
+		if !found {
+			return nil, errors.New(fmt.Sprintf("missing required parameter '%s'", name))
+		}
+		values[i] = value
+	}
+
+	sp := &slotParameters{program: this, values: values}
+
+	return this.expression.Eval(sp)
+}
@@ -0,0 +1,63 @@
+package govaluate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompileRejectsUndeclaredVariable(t *testing.T) {
+	expr, err := NewEvaluableExpression("foo + 1")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	_, err = expr.Compile(Environment{Variables: map[string]reflect.Kind{}})
+	if err == nil {
+		t.Fatal("expected Compile to reject a variable missing from the environment")
+	}
+}
+
+func TestCompileRejectsArityMismatch(t *testing.T) {
+	functions := map[string]ExpressionFunction{
+		"double": func(arguments ...interface{}) (interface{}, error) {
+			return arguments[0], nil
+		},
+	}
+
+	expr, err := NewEvaluableExpressionWithFunctions("double(1, 2)", functions)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	env := Environment{
+		Functions: map[string]FunctionSignature{
+			"double": {Inputs: []reflect.Kind{reflect.Float64}, Output: reflect.Float64},
+		},
+	}
+
+	_, err = expr.Compile(env)
+	if err == nil {
+		t.Fatal("expected Compile to reject a call with the wrong argument count")
+	}
+}
+
+func TestProgramRunResolvesEachVariableOnce(t *testing.T) {
+	expr, err := NewEvaluableExpression("foo + foo + foo")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+
+	program, err := expr.Compile(Environment{Variables: map[string]reflect.Kind{"foo": reflect.Float64}})
+	if err != nil {
+		t.Fatalf("failed to compile expression: %s", err)
+	}
+
+	result, err := program.Run(map[string]interface{}{"foo": 2.0})
+	if err != nil {
+		t.Fatalf("failed to run program: %s", err)
+	}
+
+	if result != 6.0 {
+		t.Fatalf("expected 6.0, got %v", result)
+	}
+}
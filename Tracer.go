@@ -0,0 +1,45 @@
+package govaluate
+
+/*
+Tracer receives a notification for each exported call into an EvaluableExpression,
+pairing an OnEnter with the OnExit that eventually follows it. It replaces the
+previous ellen-based tracing, which unconditionally marshaled every call's
+arguments and parameters to JSON and printed them to stdout - a fixed cost paid
+on every Eval regardless of whether anyone was listening.
+
+A nil Tracer (the default, on both EvaluableExpression and DefaultTracer) disables
+tracing entirely; every call site checks for nil before doing any work, so the
+no-op path costs a single comparison and never allocates the args/results maps.
+*/
+type Tracer interface {
+	OnEnter(name string, args map[string]interface{})
+	OnExit(name string, results map[string]interface{}, err error)
+}
+
+/*
+DefaultTracer is consulted by package-level functions - constructors, and helpers
+that have no EvaluableExpression receiver to carry a tracer on - when no
+more specific Tracer is available. It is nil, and therefore inert, until a caller
+sets it.
+*/
+var DefaultTracer Tracer
+
+/*
+SetTracer assigns the Tracer used for every subsequent call made through this
+expression, overriding DefaultTracer. Passing nil clears this expression's own
+tracer, so its calls fall back to DefaultTracer again.
+*/
+func (this *EvaluableExpression) SetTracer(tracer Tracer) {
+	this.tracer = tracer
+}
+
+/*
+tracerFor resolves the Tracer that should observe a call on [this]: its own
+tracer if one was set with SetTracer, falling back to DefaultTracer otherwise.
+*/
+func (this EvaluableExpression) tracerFor() Tracer {
+	if this.tracer != nil {
+		return this.tracer
+	}
+	return DefaultTracer
+}
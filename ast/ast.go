@@ -0,0 +1,197 @@
+/*
+Package ast exposes a stable, public mirror of a parsed expression's internal
+evaluation stage tree, in the spirit of go/ast. It exists so that callers can
+walk or rewrite a parsed expression - for constant-folding passes beyond the
+built-in optimizeTokens, for translating an expression into a SQL WHERE clause
+or a MongoDB query, or for static analysis such as "does this expression
+reference variable X outside of a short-circuited branch" - without reaching
+into govaluate's unexported evaluationStage.
+*/
+package ast
+
+/*
+Node is implemented by every kind of AST node. Kind reports which concrete
+kind a Node is, so a Visitor or Transform func can type-switch without an
+import cycle back to the node's own package.
+*/
+type Node interface {
+	Kind() Kind
+}
+
+/*
+Kind identifies the concrete type of a Node.
+*/
+type Kind int
+
+const (
+	KindLiteral Kind = iota
+	KindVariable
+	KindUnary
+	KindBinary
+	KindTernary
+	KindCall
+	KindShortCircuit
+)
+
+/*
+Literal is a constant value folded in at parse time, e.g. a number, string, or
+boolean token.
+*/
+type Literal struct {
+	Value interface{}
+}
+
+func (this *Literal) Kind() Kind { return KindLiteral }
+
+/*
+Variable is a named reference into the parameters given to Eval.
+*/
+type Variable struct {
+	Name string
+}
+
+func (this *Variable) Kind() Kind { return KindVariable }
+
+/*
+Unary is a single-operand operation, e.g. negation or logical NOT.
+*/
+type Unary struct {
+	Operator string
+	Operand  Node
+}
+
+func (this *Unary) Kind() Kind { return KindUnary }
+
+/*
+Binary is a two-operand operation, e.g. arithmetic, comparison, or a
+non-short-circuited logical operator.
+*/
+type Binary struct {
+	Operator string
+	Left     Node
+	Right    Node
+}
+
+func (this *Binary) Kind() Kind { return KindBinary }
+
+/*
+Ternary represents a condition ? trueBranch : falseBranch expression.
+*/
+type Ternary struct {
+	Condition   Node
+	TrueBranch  Node
+	FalseBranch Node
+}
+
+func (this *Ternary) Kind() Kind { return KindTernary }
+
+/*
+Call is an invocation of a named function with the given argument nodes.
+*/
+type Call struct {
+	Name      string
+	Arguments []Node
+}
+
+func (this *Call) Kind() Kind { return KindCall }
+
+/*
+ShortCircuit is a two-operand operation whose right operand is only evaluated
+conditionally - &&, ||, and the coalesce operator all build this node kind
+instead of plain Binary, so that a Visitor or Transform can tell short-circuit
+evaluation order apart from ordinary binary operators.
+*/
+type ShortCircuit struct {
+	Operator string
+	Left     Node
+	Right    Node
+}
+
+func (this *ShortCircuit) Kind() Kind { return KindShortCircuit }
+
+/*
+Visitor is called once per Node encountered by Walk, in pre-order. Returning
+false from Visit stops Walk from descending into that node's children.
+*/
+type Visitor interface {
+	Visit(node Node) (descend bool)
+}
+
+/*
+VisitorFunc adapts a plain function to the Visitor interface.
+*/
+type VisitorFunc func(node Node) bool
+
+func (this VisitorFunc) Visit(node Node) bool {
+	return this(node)
+}
+
+/*
+Walk traverses [node] and its children in pre-order, calling [visitor] on each
+one. It is safe to call with a nil node, in which case it does nothing.
+*/
+func Walk(node Node, visitor Visitor) {
+	if node == nil {
+		return
+	}
+
+	if !visitor.Visit(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Unary:
+		Walk(n.Operand, visitor)
+	case *Binary:
+		Walk(n.Left, visitor)
+		Walk(n.Right, visitor)
+	case *ShortCircuit:
+		Walk(n.Left, visitor)
+		Walk(n.Right, visitor)
+	case *Ternary:
+		Walk(n.Condition, visitor)
+		Walk(n.TrueBranch, visitor)
+		Walk(n.FalseBranch, visitor)
+	case *Call:
+		for _, arg := range n.Arguments {
+			Walk(arg, visitor)
+		}
+	}
+}
+
+/*
+Transform rebuilds [node] bottom-up, replacing each node with the result of
+calling [rewrite] on it after its children have already been rewritten. This
+lets a rewrite rule assume its children are already in final form, the same
+way go/ast.Inspect-based rewrites are usually written.
+
+Returning nil from [rewrite] for a non-root node removes that node's parent's
+reference to it; Transform leaves the affected child unset (nil) in that case,
+so callers that prune nodes must accept nil children downstream.
+*/
+func Transform(node Node, rewrite func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *Unary:
+		n.Operand = Transform(n.Operand, rewrite)
+	case *Binary:
+		n.Left = Transform(n.Left, rewrite)
+		n.Right = Transform(n.Right, rewrite)
+	case *ShortCircuit:
+		n.Left = Transform(n.Left, rewrite)
+		n.Right = Transform(n.Right, rewrite)
+	case *Ternary:
+		n.Condition = Transform(n.Condition, rewrite)
+		n.TrueBranch = Transform(n.TrueBranch, rewrite)
+		n.FalseBranch = Transform(n.FalseBranch, rewrite)
+	case *Call:
+		for i, arg := range n.Arguments {
+			n.Arguments[i] = Transform(arg, rewrite)
+		}
+	}
+
+	return rewrite(node)
+}
@@ -0,0 +1,36 @@
+/*
+Package jsontracer provides a govaluate.Tracer that reproduces the behavior of
+the old ellen-based tracing: every call's arguments and results, JSON-marshaled
+and printed to stdout. It exists for callers who want that debugging output back
+on an opt-in basis, without paying for it when no Tracer is set.
+*/
+package jsontracer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+JSONTracer is a govaluate.Tracer that marshals call arguments and results to JSON
+and writes them with fmt.Printf, one line per OnEnter and one line per OnExit.
+*/
+type JSONTracer struct{}
+
+/*
+New returns a JSONTracer ready to pass to EvaluableExpression.SetTracer or assign
+to govaluate.DefaultTracer.
+*/
+func New() *JSONTracer {
+	return &JSONTracer{}
+}
+
+func (this *JSONTracer) OnEnter(name string, args map[string]interface{}) {
+	argsBytes, _ := json.Marshal(args)
+	fmt.Printf("Entering %s, arguments:%s\n", name, string(argsBytes))
+}
+
+func (this *JSONTracer) OnExit(name string, results map[string]interface{}, err error) {
+	resultsBytes, _ := json.Marshal(results)
+	fmt.Printf("Exiting %s, returns:%s, err:%v\n", name, string(resultsBytes), err)
+}